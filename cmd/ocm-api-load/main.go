@@ -0,0 +1,88 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This is the entry point of the load-testing tool: it parses the command line flags, resolves
+// the effective test plan (built-in tests plus whatever --test-case-file adds or overrides),
+// builds the logger and the vegeta attacker, and dispatches every test in the plan.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cloud-bulldozer/ocm-api-load/pkg/logging"
+	"github.com/cloud-bulldozer/ocm-api-load/pkg/tests"
+	vegeta "github.com/tsenart/vegeta/v12/lib"
+)
+
+// clusterIDs collects every --cluster-id flag into a single slice, so tests that template over
+// ClusterIDs can be driven against more than one cluster in a single run.
+type clusterIDs []string
+
+func (c *clusterIDs) String() string {
+	return fmt.Sprint([]string(*c))
+}
+
+func (c *clusterIDs) Set(value string) error {
+	*c = append(*c, value)
+	return nil
+}
+
+func main() {
+	var (
+		rate       int
+		duration   time.Duration
+		logFile    string
+		jsonLog    bool
+		debugLog   bool
+		clusterIDs clusterIDs
+	)
+	flag.IntVar(&rate, "rate", 10, "number of requests per second to send to each test")
+	flag.DurationVar(&duration, "duration", 30*time.Second, "how long to attack each test")
+	flag.StringVar(&logFile, "log-file", "", "path to a file to write logs to instead of stderr; error and fatal records also go to stderr")
+	flag.BoolVar(&jsonLog, "json", false, "emit logs as JSON instead of human-readable text")
+	flag.BoolVar(&debugLog, "debug", false, "enable debug-level logging")
+	flag.Var(&clusterIDs, "cluster-id", "cluster ID to make available to test-case body templates (may be repeated)")
+	cfg := tests.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	log, err := logging.NewGoLoggerBuilder().
+		Debug(debugLog).
+		JSON(jsonLog).
+		LogFile(logFile).
+		Build()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "building logger: %s\n", err)
+		os.Exit(1)
+	}
+	defer log.DeferClose()
+
+	ctx := context.Background()
+
+	plan, ok, err := tests.EffectivePlan(cfg, clusterIDs, os.Stdout)
+	if err != nil {
+		log.Fatal(ctx, "resolving effective test plan: %s", err)
+	}
+	if !ok {
+		return
+	}
+
+	attacker := vegeta.NewAttacker()
+	tests.Dispatch(ctx, log, attacker, plan, vegeta.Rate{Freq: rate, Per: time.Second}, duration)
+}
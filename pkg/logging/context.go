@@ -0,0 +1,83 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file lets callers stash Fields on a context.Context so that everything logged while that
+// context is in flight — across function boundaries, goroutines started from it, etc — carries
+// them automatically, without every call site having to repeat them.
+
+package logging
+
+import "context"
+
+type contextFieldsKey struct{}
+
+// WithFields returns a copy of ctx carrying fields in addition to any it already carries. Fields
+// added later take precedence over earlier ones with the same key when both end up attached to
+// the same record.
+func WithFields(ctx context.Context, fields ...Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	existing := FieldsFrom(ctx)
+	merged := make([]Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, contextFieldsKey{}, dedupeFields(merged))
+}
+
+// FieldsFrom returns the Fields previously attached to ctx with WithFields, or nil if there are
+// none.
+func FieldsFrom(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(contextFieldsKey{}).([]Field)
+	return fields
+}
+
+// mergeFields appends call-site fields after the ones carried by ctx, so that a field passed
+// directly to a log call can override one of the same name stashed on the context.
+func mergeFields(ctx context.Context, fields []Field) []Field {
+	ctxFields := FieldsFrom(ctx)
+	if len(ctxFields) == 0 {
+		return fields
+	}
+	if len(fields) == 0 {
+		return ctxFields
+	}
+	merged := make([]Field, 0, len(ctxFields)+len(fields))
+	merged = append(merged, ctxFields...)
+	merged = append(merged, fields...)
+	return dedupeFields(merged)
+}
+
+// dedupeFields collapses fields down to one entry per key, keeping the value of the last
+// occurrence but the position of the first, so that records rendered as text (which print every
+// field in order) agree with records rendered as JSON (where a later value simply overwrites the
+// earlier one in the output map) about which value wins for a repeated key.
+func dedupeFields(fields []Field) []Field {
+	positions := make(map[string]int, len(fields))
+	deduped := make([]Field, 0, len(fields))
+	for _, field := range fields {
+		if i, ok := positions[field.key]; ok {
+			deduped[i] = field
+			continue
+		}
+		positions[field.key] = len(deduped)
+		deduped = append(deduped, field)
+	}
+	return deduped
+}
@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithFieldsAccumulates(t *testing.T) {
+	ctx := WithFields(context.Background(), String("a", "1"))
+	ctx = WithFields(ctx, String("b", "2"))
+
+	got := FieldsFrom(ctx)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 fields, got %d: %#v", len(got), got)
+	}
+	if got[0].Key() != "a" || got[1].Key() != "b" {
+		t.Errorf("expected fields in order [a b], got %#v", got)
+	}
+}
+
+func TestWithFieldsLaterValueWins(t *testing.T) {
+	ctx := WithFields(context.Background(), String("k", "first"))
+	ctx = WithFields(ctx, String("k", "second"))
+
+	got := FieldsFrom(ctx)
+	if len(got) != 1 {
+		t.Fatalf("expected duplicate key to collapse to 1 field, got %d: %#v", len(got), got)
+	}
+	if got[0].Value() != "second" {
+		t.Errorf("expected the later value to win, got %#v", got[0].Value())
+	}
+}
+
+func TestFieldsFromEmptyContext(t *testing.T) {
+	if got := FieldsFrom(context.Background()); got != nil {
+		t.Errorf("expected nil fields from an empty context, got %#v", got)
+	}
+	if got := FieldsFrom(nil); got != nil {
+		t.Errorf("expected nil fields from a nil context, got %#v", got)
+	}
+}
+
+func TestMergeFieldsLaterValueWins(t *testing.T) {
+	ctx := WithFields(context.Background(), String("k", "ctx"))
+	merged := mergeFields(ctx, []Field{String("k", "call")})
+
+	if len(merged) != 1 {
+		t.Fatalf("expected duplicate key to collapse to 1 field, got %d: %#v", len(merged), merged)
+	}
+	if merged[0].Value() != "call" {
+		t.Errorf("expected the call-site value to win over the context value, got %#v", merged[0].Value())
+	}
+}
+
+func TestDedupeFieldsKeepsFirstPosition(t *testing.T) {
+	deduped := dedupeFields([]Field{
+		String("a", "1"),
+		String("b", "2"),
+		String("a", "3"),
+	})
+
+	if len(deduped) != 2 {
+		t.Fatalf("expected 2 fields, got %d: %#v", len(deduped), deduped)
+	}
+	if deduped[0].Key() != "a" || deduped[0].Value() != "3" {
+		t.Errorf("expected field 0 to be a=3, got %#v", deduped[0])
+	}
+	if deduped[1].Key() != "b" || deduped[1].Value() != "2" {
+		t.Errorf("expected field 1 to be b=2, got %#v", deduped[1])
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the typed-field API used by the structured logging methods of GoLogger
+// (Debugw/Infow/Warnw/Errorw). It is intentionally small and avoids boxing values in
+// `interface{}` for the common field types, in the same spirit as zap's typed field helpers.
+
+package logging
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// fieldType identifies which member of Field is populated.
+type fieldType int
+
+const (
+	stringType fieldType = iota
+	int32Type
+	int64Type
+	float64Type
+	boolType
+	durationType
+	errType
+)
+
+// Field is a single typed key/value pair attached to a log record. Use the String, Int32, Int64,
+// Float64, Bool, Duration and Err helpers to build one instead of populating it directly.
+type Field struct {
+	key string
+	typ fieldType
+	str string
+	num int64
+	dur time.Duration
+	err error
+}
+
+// String creates a field carrying a string value.
+func String(key string, value string) Field {
+	return Field{key: key, typ: stringType, str: value}
+}
+
+// Int32 creates a field carrying an int32 value.
+func Int32(key string, value int32) Field {
+	return Field{key: key, typ: int32Type, num: int64(value)}
+}
+
+// Int64 creates a field carrying an int64 value.
+func Int64(key string, value int64) Field {
+	return Field{key: key, typ: int64Type, num: value}
+}
+
+// Float64 creates a field carrying a float64 value.
+func Float64(key string, value float64) Field {
+	return Field{key: key, typ: float64Type, num: int64(math.Float64bits(value))}
+}
+
+// Bool creates a field carrying a boolean value.
+func Bool(key string, value bool) Field {
+	n := int64(0)
+	if value {
+		n = 1
+	}
+	return Field{key: key, typ: boolType, num: n}
+}
+
+// Duration creates a field carrying a time.Duration value.
+func Duration(key string, value time.Duration) Field {
+	return Field{key: key, typ: durationType, dur: value}
+}
+
+// Err creates a field named "error" carrying the given error. A nil error is rendered as the
+// empty string rather than being omitted, so callers can tell the difference between "no error
+// field was passed" and "the error field was passed as nil".
+func Err(err error) Field {
+	return Field{key: "error", typ: errType, err: err}
+}
+
+// Key returns the field name.
+func (f Field) Key() string {
+	return f.key
+}
+
+// Value returns the field's value as an interface{}, suitable for JSON encoding.
+func (f Field) Value() interface{} {
+	switch f.typ {
+	case stringType:
+		return f.str
+	case int32Type:
+		return int32(f.num)
+	case int64Type:
+		return f.num
+	case float64Type:
+		return math.Float64frombits(uint64(f.num))
+	case boolType:
+		return f.num != 0
+	case durationType:
+		return f.dur.String()
+	case errType:
+		if f.err == nil {
+			return ""
+		}
+		return f.err.Error()
+	default:
+		return nil
+	}
+}
+
+// String renders the field as it appears in human-readable (non-JSON) log lines: `key=value`.
+func (f Field) String() string {
+	return fmt.Sprintf("%s=%v", f.key, f.Value())
+}
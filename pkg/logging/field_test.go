@@ -0,0 +1,73 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFieldValue(t *testing.T) {
+	cases := []struct {
+		name  string
+		field Field
+		want  interface{}
+	}{
+		{"string", String("k", "v"), "v"},
+		{"int32", Int32("k", -7), int32(-7)},
+		{"int64", Int64("k", 1<<40), int64(1 << 40)},
+		{"float64 whole", Float64("k", 3), float64(3)},
+		{"float64 fractional", Float64("k", 3.14159), 3.14159},
+		{"bool true", Bool("k", true), true},
+		{"bool false", Bool("k", false), false},
+		{"duration", Duration("k", 2*time.Second), "2s"},
+		{"err", Err(errors.New("boom")), "boom"},
+		{"nil err", Err(nil), ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.field.Value(); got != c.want {
+				t.Errorf("Value() = %#v, want %#v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestFieldFloat64RoundTripsExactly(t *testing.T) {
+	for _, value := range []float64{0, 3.14159, -1.5, 1e300, 4503599627370497} {
+		f := Float64("k", value)
+		if got := f.Value().(float64); got != value {
+			t.Errorf("Float64(%v).Value() = %v, want %v", value, got, value)
+		}
+	}
+}
+
+func TestFieldKey(t *testing.T) {
+	if got := String("name", "value").Key(); got != "name" {
+		t.Errorf("Key() = %q, want %q", got, "name")
+	}
+}
+
+func TestFieldString(t *testing.T) {
+	if got, want := String("k", "v").String(), "k=v"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := Int32("n", 5).String(), "n=5"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -20,32 +20,52 @@ package logging
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"io"
+	"runtime"
+	"time"
 )
 
+// defaultCallerSkip is the number of stack frames between runtime.Caller and the user code that
+// called one of the GoLogger methods (Debug, Infow, etc). It is exposed as a builder option
+// because anything that wraps GoLogger with its own helper methods needs to add one frame per
+// layer of wrapping.
+const defaultCallerSkip = 3
+
 // GoLoggerBuilder contains the configuration and logic needed to build a logger that uses the Go
 // `log` package. Don't create instances of this type directly, use the NewGoLoggerBuilder function
 // instead.
 type GoLoggerBuilder struct {
-	debugEnabled bool
-	infoEnabled  bool
-	warnEnabled  bool
-	errorEnabled bool
-	logFile string
+	debugEnabled      bool
+	infoEnabled       bool
+	warnEnabled       bool
+	errorEnabled      bool
+	jsonEnabled       bool
+	callerSkip        int
+	logFile           string
+	logFileMaxSizeMB  int
+	logFileMaxBackups int
+	logFileMaxAgeDays int
+	logFileCompress   bool
 }
 
 // GoLogger is a logger that uses the Go `log` package.
 type GoLogger struct {
-	debugEnabled bool
-	infoEnabled  bool
-	warnEnabled  bool
-	errorEnabled bool
+	debugEnabled   bool
+	infoEnabled    bool
+	warnEnabled    bool
+	errorEnabled   bool
+	jsonEnabled    bool
+	callerSkip     int
 	logFileEnabled bool
-	logFile string
-	logFileToClose *os.File
+	logFile        string
+	logFileToClose io.Closer
+	rotWriter      *rotatingWriter
+	out            io.Writer
+	errOut         io.Writer
 }
 
 // NewGoLoggerBuilder creates a builder that knows how to build a logger that uses the Go `log`
@@ -59,7 +79,13 @@ func NewGoLoggerBuilder() *GoLoggerBuilder {
 	builder.infoEnabled = true
 	builder.warnEnabled = true
 	builder.errorEnabled = true
-        builder.logFile = ""
+	builder.jsonEnabled = false
+	builder.callerSkip = defaultCallerSkip
+	builder.logFile = ""
+	builder.logFileMaxSizeMB = 0
+	builder.logFileMaxBackups = 0
+	builder.logFileMaxAgeDays = 0
+	builder.logFileCompress = false
 
 	return builder
 }
@@ -88,10 +114,55 @@ func (b *GoLoggerBuilder) Error(flag bool) *GoLoggerBuilder {
 	return b
 }
 
+// JSON enables or disables JSON output. When enabled every record is written as a single JSON
+// object with `ts`, `level`, `caller` and `msg` keys plus one key per attached Field; when
+// disabled (the default) records are written as human-readable
+// `LEVEL: file:line: message key=value ...` lines.
+func (b *GoLoggerBuilder) JSON(flag bool) *GoLoggerBuilder {
+	b.jsonEnabled = flag
+	return b
+}
+
+// CallerSkip sets the total number of stack frames to skip when computing the `file:line`
+// reported for each record, replacing the default of defaultCallerSkip. It only needs to be
+// changed when GoLogger is wrapped by another type that exposes its own Debug/Info/... methods,
+// in which case it should be defaultCallerSkip plus one per layer of wrapping.
+func (b *GoLoggerBuilder) CallerSkip(skip int) *GoLoggerBuilder {
+	b.callerSkip = skip
+	return b
+}
+
 // Set log file location
 func (b *GoLoggerBuilder) LogFile(flag string) *GoLoggerBuilder {
-        b.logFile = flag
-        return b
+	b.logFile = flag
+	return b
+}
+
+// LogFileMaxSizeMB sets the size in megabytes a log file can reach before it is rotated. Zero (the
+// default) disables size-based rotation.
+func (b *GoLoggerBuilder) LogFileMaxSizeMB(size int) *GoLoggerBuilder {
+	b.logFileMaxSizeMB = size
+	return b
+}
+
+// LogFileMaxBackups sets how many rotated log files are kept around. Zero (the default) keeps all
+// of them.
+func (b *GoLoggerBuilder) LogFileMaxBackups(count int) *GoLoggerBuilder {
+	b.logFileMaxBackups = count
+	return b
+}
+
+// LogFileMaxAgeDays sets how many days a rotated log file is kept before it is deleted. Zero (the
+// default) disables age-based cleanup.
+func (b *GoLoggerBuilder) LogFileMaxAgeDays(days int) *GoLoggerBuilder {
+	b.logFileMaxAgeDays = days
+	return b
+}
+
+// LogFileCompress enables gzip compression of rotated log files.
+func (b *GoLoggerBuilder) LogFileCompress(flag bool) *GoLoggerBuilder {
+	b.logFileCompress = flag
+	return b
 }
 
 // Build creates a new logger using the configuration stored in the builder.
@@ -102,16 +173,22 @@ func (b *GoLoggerBuilder) Build() (logger *GoLogger, err error) {
 	logger.infoEnabled = b.infoEnabled
 	logger.warnEnabled = b.warnEnabled
 	logger.errorEnabled = b.errorEnabled
+	logger.jsonEnabled = b.jsonEnabled
+	logger.callerSkip = b.callerSkip
 	logger.logFile = b.logFile
+	logger.out = os.Stderr
+	logger.errOut = os.Stderr
 
 	if b.logFile != "" {
-                lFile, err := os.OpenFile(b.logFile, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
-                if err != nil {
-                        log.Fatalf("Error opening log-file for writing: %v\n", err)
-                }
-		logger.logFileToClose = lFile
-                logger.SetOutput(lFile)
-        }
+		rw, err := newRotatingWriter(b.logFile, b.logFileMaxSizeMB, b.logFileMaxBackups, b.logFileMaxAgeDays, b.logFileCompress)
+		if err != nil {
+			log.Fatalf("Error opening log-file for writing: %v\n", err)
+		}
+		logger.rotWriter = rw
+		logger.logFileToClose = rw
+		logger.logFileEnabled = true
+		logger.SetOutput(rw)
+	}
 
 	return
 }
@@ -138,20 +215,22 @@ func (l *GoLogger) ErrorEnabled() bool {
 
 // LogFileEnabled returns true iff there is a log file set
 func (l *GoLogger) LogFileEnabled() bool {
-        if l.logFile != "" {
-                return true
-        }
-        return false
+	return l.logFileEnabled
 }
 
 // Debug sends to the log a debug message formatted using the fmt.Sprintf function and the given
 // format and arguments.
 func (l *GoLogger) Debug(ctx context.Context, format string, args ...interface{}) {
 	if l.debugEnabled {
-		format = appendHeader(Debug, format)
 		msg := fmt.Sprintf(format, args...)
-		// #nosec G104
-		log.Output(1, msg)
+		l.emit(l.out, Debug, msg, mergeFields(ctx, nil))
+	}
+}
+
+// Debugw sends to the log a debug message with the given typed fields attached.
+func (l *GoLogger) Debugw(ctx context.Context, msg string, fields ...Field) {
+	if l.debugEnabled {
+		l.emit(l.out, Debug, msg, mergeFields(ctx, fields))
 	}
 }
 
@@ -159,10 +238,15 @@ func (l *GoLogger) Debug(ctx context.Context, format string, args ...interface{}
 // given format and arguments.
 func (l *GoLogger) Info(ctx context.Context, format string, args ...interface{}) {
 	if l.infoEnabled {
-		format = appendHeader(Info, format)
 		msg := fmt.Sprintf(format, args...)
-		// #nosec G104
-		log.Output(1, msg)
+		l.emit(l.out, Info, msg, mergeFields(ctx, nil))
+	}
+}
+
+// Infow sends to the log an information message with the given typed fields attached.
+func (l *GoLogger) Infow(ctx context.Context, msg string, fields ...Field) {
+	if l.infoEnabled {
+		l.emit(l.out, Info, msg, mergeFields(ctx, fields))
 	}
 }
 
@@ -170,10 +254,15 @@ func (l *GoLogger) Info(ctx context.Context, format string, args ...interface{})
 // format and arguments.
 func (l *GoLogger) Warn(ctx context.Context, format string, args ...interface{}) {
 	if l.warnEnabled {
-		format = appendHeader(Warning, format)
 		msg := fmt.Sprintf(format, args...)
-		// #nosec G104
-		log.Output(1, msg)
+		l.emit(l.out, Warning, msg, mergeFields(ctx, nil))
+	}
+}
+
+// Warnw sends to the log a warning message with the given typed fields attached.
+func (l *GoLogger) Warnw(ctx context.Context, msg string, fields ...Field) {
+	if l.warnEnabled {
+		l.emit(l.out, Warning, msg, mergeFields(ctx, fields))
 	}
 }
 
@@ -181,17 +270,15 @@ func (l *GoLogger) Warn(ctx context.Context, format string, args ...interface{})
 // format and arguments.
 func (l *GoLogger) Error(ctx context.Context, format string, args ...interface{}) {
 	if l.errorEnabled {
-		format = appendHeader(Error, format)
 		msg := fmt.Sprintf(format, args...)
-		// #nosec G104
-		if l.LogFileEnabled() {
-                        mw := io.MultiWriter(log.Writer(), os.Stderr)
-                        log.SetOutput(mw)
-                }
-                log.Output(1, msg)
-                if l.LogFileEnabled() {
-                        log.SetOutput(l.logFileToClose)
-                }
+		l.emit(l.errDestination(), Error, msg, mergeFields(ctx, nil))
+	}
+}
+
+// Errorw sends to the log an error message with the given typed fields attached.
+func (l *GoLogger) Errorw(ctx context.Context, msg string, fields ...Field) {
+	if l.errorEnabled {
+		l.emit(l.errDestination(), Error, msg, mergeFields(ctx, fields))
 	}
 }
 
@@ -199,26 +286,79 @@ func (l *GoLogger) Error(ctx context.Context, format string, args ...interface{}
 // format and arguments. After that it will os.Exit(1)
 // This level is always enabled
 func (l *GoLogger) Fatal(ctx context.Context, format string, args ...interface{}) {
-	format = appendHeader(Fatal, format)
 	msg := fmt.Sprintf(format, args...)
-	// #nosec G104
-	if l.LogFileEnabled() {
-                mw := io.MultiWriter(log.Writer(), os.Stderr)
-                log.SetOutput(mw)
-        }
-	log.Output(1, msg)
+	l.emit(l.errDestination(), Fatal, msg, mergeFields(ctx, nil))
 	l.DeferClose()
 	os.Exit(1)
 }
 
+// errDestination returns the writer that error and fatal records should go to: the log file plus
+// stderr when a log file is configured (so operators tailing the pod's stderr still see errors),
+// or just stderr otherwise.
+func (l *GoLogger) errDestination() io.Writer {
+	if l.logFileEnabled {
+		return io.MultiWriter(l.out, l.errOut)
+	}
+	return l.out
+}
+
+// emit renders a single record to w, either as JSON or as a human-readable line, depending on how
+// the logger was built.
+func (l *GoLogger) emit(w io.Writer, level Level, msg string, fields []Field) {
+	caller := callerInfo(l.callerSkip)
+	if l.jsonEnabled {
+		l.emitJSON(w, level, caller, msg, fields)
+		return
+	}
+	l.emitText(w, level, caller, msg, fields)
+}
+
+func (l *GoLogger) emitText(w io.Writer, level Level, caller string, msg string, fields []Field) {
+	line := fmt.Sprintf("%s: %s: %s", level, caller, msg)
+	for _, field := range fields {
+		line += " " + field.String()
+	}
+	// #nosec G104
+	fmt.Fprintln(w, line)
+}
+
+func (l *GoLogger) emitJSON(w io.Writer, level Level, caller string, msg string, fields []Field) {
+	record := make(map[string]interface{}, len(fields)+4)
+	record["ts"] = time.Now().UTC().Format(time.RFC3339Nano)
+	record["level"] = fmt.Sprintf("%s", level)
+	record["caller"] = caller
+	record["msg"] = msg
+	for _, field := range fields {
+		record[field.Key()] = field.Value()
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		// Fall back to a text line rather than losing the record entirely.
+		l.emitText(w, level, caller, msg, fields)
+		return
+	}
+	// #nosec G104
+	fmt.Fprintln(w, string(data))
+}
+
+// callerInfo returns the "file:line" of the caller `skip` frames up from this function, falling
+// back to "???:0" when the runtime can't resolve it.
+func callerInfo(skip int) string {
+	_, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return "???:0"
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
 // Set the output of the logger to a log file
 func (l *GoLogger) SetOutput(w io.Writer) {
-        log.SetOutput(w)
+	l.out = w
 }
 
 // If we are writing to a log file, close it
 func (l *GoLogger) DeferClose() {
-        if l.LogFileEnabled() {
-                l.logFileToClose.Close()
-        }
+	if l.logFileEnabled && l.logFileToClose != nil {
+		l.logFileToClose.Close()
+	}
 }
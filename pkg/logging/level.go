@@ -0,0 +1,58 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the Level type used to tag log records.
+
+package logging
+
+// Level identifies the severity of a log record.
+type Level int
+
+const (
+	// Debug identifies debug messages.
+	Debug Level = iota
+
+	// Info identifies information messages.
+	Info
+
+	// Warning identifies warning messages.
+	Warning
+
+	// Error identifies error messages.
+	Error
+
+	// Fatal identifies fatal error messages.
+	Fatal
+)
+
+// String returns the name of the level, as used in both the human-readable and JSON record
+// formats.
+func (l Level) String() string {
+	switch l {
+	case Debug:
+		return "DEBUG"
+	case Info:
+		return "INFO"
+	case Warning:
+		return "WARNING"
+	case Error:
+		return "ERROR"
+	case Fatal:
+		return "FATAL"
+	default:
+		return "UNKNOWN"
+	}
+}
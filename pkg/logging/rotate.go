@@ -0,0 +1,272 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the rotatingWriter used by the file backend of GoLogger. It rotates the log
+// file by size, keeps at most a configured number of backups (and age), optionally compresses
+// them, and reopens the current segment on SIGHUP so that external `logrotate` setups keep
+// working.
+
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// rotatingWriter is an io.WriteCloser that wraps a single log file on disk and rotates it once it
+// grows past maxSizeBytes. It is safe for concurrent use.
+type rotatingWriter struct {
+	mu           sync.Mutex
+	path         string
+	maxSizeBytes int64
+	maxBackups   int
+	maxAgeDays   int
+	compress     bool
+
+	file     *os.File
+	written  int64
+	degraded bool
+
+	sigCh chan os.Signal
+	done  chan struct{}
+}
+
+// newRotatingWriter opens (creating if necessary) the log file at path and returns a writer that
+// rotates it once it grows past maxSizeMB megabytes, keeping at most maxBackups backups no older
+// than maxAgeDays. A maxSizeMB, maxBackups or maxAgeDays of 0 disables that particular limit.
+// When compress is true, rotated backups are gzipped in the background.
+func newRotatingWriter(path string, maxSizeMB, maxBackups, maxAgeDays int, compress bool) (*rotatingWriter, error) {
+	w := &rotatingWriter{
+		path:         path,
+		maxSizeBytes: int64(maxSizeMB) * 1024 * 1024,
+		maxBackups:   maxBackups,
+		maxAgeDays:   maxAgeDays,
+		compress:     compress,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	w.watchSignals()
+	return w, nil
+}
+
+// openNew opens a fresh handle on w.path without touching w.file, so callers can confirm the new
+// handle works before giving up the old one.
+func (w *rotatingWriter) openNew() (*os.File, int64, error) {
+	file, err := os.OpenFile(w.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+	return file, info.Size(), nil
+}
+
+func (w *rotatingWriter) open() error {
+	file, size, err := w.openNew()
+	if err != nil {
+		return err
+	}
+	w.file = file
+	w.written = size
+	return nil
+}
+
+// Write implements io.Writer, rotating the underlying file first if p would push it past the
+// configured size limit. If the writer is in a degraded state (the file couldn't be (re)opened),
+// it writes to stderr instead of going dark.
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.degraded || w.file == nil {
+		return os.Stderr.Write(p)
+	}
+
+	if w.maxSizeBytes > 0 && w.written > 0 && w.written+int64(len(p)) > w.maxSizeBytes {
+		if err := w.rotateLocked(); err != nil {
+			return os.Stderr.Write(p)
+		}
+	}
+	n, err := w.file.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
+// Reopen opens a new handle on the current segment without renaming it, honoring SIGHUP so that
+// an external `logrotate` that already moved the file still gets picked up. The existing handle is
+// kept open and in use until the new one is confirmed working, so a failure here (e.g. permissions
+// or disk issues) degrades to stderr instead of silently discarding every future Write.
+func (w *rotatingWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	file, size, err := w.openNew()
+	if err != nil {
+		w.degraded = true
+		return err
+	}
+	old := w.file
+	w.file = file
+	w.written = size
+	w.degraded = false
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// rotateLocked renames the currently open segment aside and opens a fresh one at the same path.
+// The rename doesn't require closing the file first - on POSIX, an open fd keeps working after its
+// directory entry is renamed or removed - so the old handle is only closed once the new one is
+// confirmed open, mirroring Reopen's fallback behavior on failure.
+func (w *rotatingWriter) rotateLocked() error {
+	backupPath := fmt.Sprintf("%s.%s", w.path, time.Now().UTC().Format("20060102T150405.000000000"))
+	if err := os.Rename(w.path, backupPath); err != nil {
+		return err
+	}
+	if w.compress {
+		go compressAndRemove(backupPath)
+	}
+
+	file, size, err := w.openNew()
+	if err != nil {
+		w.degraded = true
+		return err
+	}
+	old := w.file
+	w.file = file
+	w.written = size
+	w.degraded = false
+	if old != nil {
+		old.Close()
+	}
+	go w.pruneBackups()
+	return nil
+}
+
+// watchSignals starts a goroutine that reopens the current segment whenever the process receives
+// SIGHUP, mirroring the behavior external logrotate expects from long-running daemons.
+func (w *rotatingWriter) watchSignals() {
+	w.sigCh = make(chan os.Signal, 1)
+	w.done = make(chan struct{})
+	signal.Notify(w.sigCh, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-w.sigCh:
+				// #nosec G104
+				w.Reopen()
+			case <-w.done:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the SIGHUP watcher and closes the current segment.
+func (w *rotatingWriter) Close() error {
+	if w.done != nil {
+		signal.Stop(w.sigCh)
+		close(w.done)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Close()
+}
+
+// pruneBackups removes backups past the configured count and age. It runs in the background
+// after each rotation and is best-effort: errors are ignored, as a failure to clean up old
+// backups must never take down the writer that is actively serving log traffic.
+func (w *rotatingWriter) pruneBackups() {
+	dir := filepath.Dir(w.path)
+	base := filepath.Base(w.path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, base+".") {
+			continue
+		}
+		backups = append(backups, filepath.Join(dir, name))
+	}
+	sort.Strings(backups)
+
+	if w.maxAgeDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -w.maxAgeDays)
+		kept := backups[:0]
+		for _, backup := range backups {
+			info, err := os.Stat(backup)
+			if err != nil || info.ModTime().Before(cutoff) {
+				os.Remove(backup)
+				continue
+			}
+			kept = append(kept, backup)
+		}
+		backups = kept
+	}
+
+	if w.maxBackups > 0 && len(backups) > w.maxBackups {
+		for _, backup := range backups[:len(backups)-w.maxBackups] {
+			os.Remove(backup)
+		}
+	}
+}
+
+// compressAndRemove gzips path into path+".gz" and removes the uncompressed copy. It is run in
+// its own goroutine so rotation never blocks on I/O for a backup nobody is reading yet.
+func compressAndRemove(path string) {
+	src, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return
+	}
+	defer dst.Close()
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		return
+	}
+	if err := gz.Close(); err != nil {
+		return
+	}
+	os.Remove(path)
+}
@@ -0,0 +1,171 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotatingWriterWritesToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	w, err := newRotatingWriter(path, 0, 0, 0, false)
+	if err != nil {
+		t.Fatalf("newRotatingWriter returned an error: %s", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("Write returned an error: %s", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading log file: %s", err)
+	}
+	if string(data) != "hello\n" {
+		t.Errorf("expected log file to contain %q, got %q", "hello\n", string(data))
+	}
+}
+
+func TestRotatingWriterRotatesPastMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	w, err := newRotatingWriter(path, 0, 0, 0, false)
+	if err != nil {
+		t.Fatalf("newRotatingWriter returned an error: %s", err)
+	}
+	defer w.Close()
+	// newRotatingWriter doesn't expose maxSizeMB in bytes directly; set it small for the test.
+	w.maxSizeBytes = 10
+
+	if _, err := w.Write([]byte("0123456789")); err != nil {
+		t.Fatalf("first write returned an error: %s", err)
+	}
+	if _, err := w.Write([]byte("0123456789")); err != nil {
+		t.Fatalf("second write returned an error: %s", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("reading log dir: %s", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 files (current segment + 1 backup) after rotation, got %d: %v", len(entries), entries)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading current segment: %s", err)
+	}
+	if string(data) != "0123456789" {
+		t.Errorf("expected current segment to contain only the second write, got %q", string(data))
+	}
+}
+
+func TestRotatingWriterPrunesMaxBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	w, err := newRotatingWriter(path, 0, 1, 0, false)
+	if err != nil {
+		t.Fatalf("newRotatingWriter returned an error: %s", err)
+	}
+	defer w.Close()
+	w.maxSizeBytes = 1
+
+	for i := 0; i < 3; i++ {
+		if _, err := w.Write([]byte("x")); err != nil {
+			t.Fatalf("write %d returned an error: %s", i, err)
+		}
+		// rotateLocked prunes backups in a goroutine; give it a moment to finish before the next
+		// write forces another rotation.
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("reading log dir: %s", err)
+	}
+	if len(entries) > 2 {
+		t.Errorf("expected at most 2 files (current segment + 1 backup) after pruning, got %d: %v", len(entries), entries)
+	}
+}
+
+func TestRotatingWriterReopenPicksUpRenamedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	w, err := newRotatingWriter(path, 0, 0, 0, false)
+	if err != nil {
+		t.Fatalf("newRotatingWriter returned an error: %s", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("before\n")); err != nil {
+		t.Fatalf("write returned an error: %s", err)
+	}
+
+	if err := os.Rename(path, path+".rotated"); err != nil {
+		t.Fatalf("renaming log file: %s", err)
+	}
+
+	if err := w.Reopen(); err != nil {
+		t.Fatalf("Reopen returned an error: %s", err)
+	}
+	if w.degraded {
+		t.Error("expected writer not to be degraded after a successful Reopen")
+	}
+
+	if _, err := w.Write([]byte("after\n")); err != nil {
+		t.Fatalf("write after Reopen returned an error: %s", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading reopened log file: %s", err)
+	}
+	if string(data) != "after\n" {
+		t.Errorf("expected reopened file to contain %q, got %q", "after\n", string(data))
+	}
+}
+
+func TestRotatingWriterDegradesToStderrWhenFileMissing(t *testing.T) {
+	r, fake, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("creating pipe: %s", err)
+	}
+	realStderr := os.Stderr
+	os.Stderr = fake
+	defer func() { os.Stderr = realStderr }()
+
+	w := &rotatingWriter{degraded: true}
+	n, err := w.Write([]byte("x"))
+	fake.Close()
+	if err != nil {
+		t.Fatalf("Write returned an error: %s", err)
+	}
+	if n != 1 {
+		t.Errorf("expected Write to report 1 byte written, got %d", n)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("reading piped stderr: %s", err)
+	}
+	if string(data) != "x" {
+		t.Errorf("expected degraded Write to go to stderr as %q, got %q", "x", string(data))
+	}
+}
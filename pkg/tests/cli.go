@@ -0,0 +1,72 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file is what the command wiring calls to turn the --test-case-file and --print-tests flags
+// into the plan that actually gets run.
+
+package tests
+
+import (
+	"flag"
+	"io"
+
+	"github.com/cloud-bulldozer/ocm-api-load/pkg/helpers"
+)
+
+// Config holds the flags that control which test cases are run.
+type Config struct {
+	// TestCaseFile is the path to a YAML/JSON file of test cases to merge with (or override
+	// entries of) the built-in set. Empty means only the built-in set is used.
+	TestCaseFile string
+
+	// PrintTests, when set, makes EffectivePlan dump the effective merged plan instead of
+	// returning it for a run.
+	PrintTests bool
+}
+
+// RegisterFlags registers the --test-case-file and --print-tests flags on fs and returns the
+// Config they populate once fs.Parse has run.
+func RegisterFlags(fs *flag.FlagSet) *Config {
+	cfg := &Config{}
+	fs.StringVar(&cfg.TestCaseFile, "test-case-file", "",
+		"path to a YAML/JSON file of test cases to merge with (or override entries of) the built-in set")
+	fs.BoolVar(&cfg.PrintTests, "print-tests", false,
+		"print the effective, merged test plan as JSON and exit")
+	return cfg
+}
+
+// EffectivePlan resolves cfg into the test plan that should actually be run: the built-in table,
+// extended/overridden by whatever cfg.TestCaseFile declares. If cfg.PrintTests is set, the plan is
+// written to stdout as JSON and ok is false, telling the caller to stop instead of attacking
+// anything.
+func EffectivePlan(cfg *Config, clusterIDs []string, stdout io.Writer) (plan []helpers.TestOptions, ok bool, err error) {
+	var extra []helpers.TestOptions
+	if cfg.TestCaseFile != "" {
+		extra, err = LoadTestCases(cfg.TestCaseFile, clusterIDs)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	plan = MergeTestCases(extra)
+
+	if cfg.PrintTests {
+		if err := PrintTests(stdout, plan); err != nil {
+			return nil, false, err
+		}
+		return plan, false, nil
+	}
+	return plan, true, nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file drives the vegeta attack for each test in the plan and makes sure every log line
+// emitted along the way carries enough context to be grepped out of a mixed run and joined
+// against the vegeta result JSONs.
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/cloud-bulldozer/ocm-api-load/pkg/helpers"
+	"github.com/cloud-bulldozer/ocm-api-load/pkg/logging"
+	vegeta "github.com/tsenart/vegeta/v12/lib"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// contextForTest returns a copy of ctx with the fields identifying the test (its name, the HTTP
+// method and path it drives) attached, so that every log line emitted while it runs - including
+// the per-attempt fields the attack loop below adds for each request - can be grepped out of a
+// mixed run or joined against the vegeta result JSONs.
+func contextForTest(ctx context.Context, test helpers.TestOptions) context.Context {
+	return logging.WithFields(ctx,
+		logging.String("test", test.TestName),
+		logging.String("method", test.Method),
+		logging.String("path", test.Path),
+	)
+}
+
+// contextForAttempt returns a copy of ctx with the per-attempt fields attached: the attempt
+// number, the response status and latency, the OCM request id echoed back in the
+// `X-Operation-ID` header, and the OpenTelemetry trace/span id when ctx carries one.
+func contextForAttempt(ctx context.Context, attempt int32, res *vegeta.Result) context.Context {
+	ctx = logging.WithFields(ctx,
+		logging.Int32("attempt", attempt),
+		logging.Int32("status", int32(res.Code)),
+		logging.Int64("latency_ms", res.Latency.Milliseconds()),
+		logging.String("op_id", res.Headers.Get("X-Operation-ID")),
+	)
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		ctx = logging.WithFields(ctx,
+			logging.String("trace_id", span.TraceID().String()),
+			logging.String("span_id", span.SpanID().String()),
+		)
+	}
+	return ctx
+}
+
+// RunTest drives a single vegeta attack for test and logs one record per attempt, seeding each
+// one's context with the attempt/status/latency_ms/op_id (and trace/span id, if any) fields.
+func RunTest(ctx context.Context, log *logging.GoLogger, attacker *vegeta.Attacker, test helpers.TestOptions, rate vegeta.Rate, duration time.Duration) []*vegeta.Result {
+	ctx = contextForTest(ctx, test)
+	targeter := vegeta.NewStaticTargeter(vegeta.Target{
+		Method: test.Method,
+		URL:    test.Path,
+		Body:   test.Body,
+		Header: toHTTPHeader(HeadersFor(test.TestName)),
+	})
+
+	var results []*vegeta.Result
+	var attempt int32
+	for res := range attacker.Attack(targeter, rate, duration, test.TestName) {
+		attempt++
+		attemptCtx := contextForAttempt(ctx, attempt, res)
+		if res.Error != "" {
+			log.Errorw(attemptCtx, "attempt failed", logging.String("error", res.Error))
+		} else {
+			log.Debugw(attemptCtx, "attempt completed")
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// Dispatch runs every test in plan through RunTest and returns each one's results keyed by test
+// name.
+func Dispatch(ctx context.Context, log *logging.GoLogger, attacker *vegeta.Attacker, plan []helpers.TestOptions, rate vegeta.Rate, duration time.Duration) map[string][]*vegeta.Result {
+	results := make(map[string][]*vegeta.Result, len(plan))
+	for _, test := range plan {
+		results[test.TestName] = RunTest(ctx, log, attacker, test, rate, duration)
+	}
+	return results
+}
+
+func toHTTPHeader(headers map[string]string) http.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	h := make(http.Header, len(headers))
+	for k, v := range headers {
+		h.Set(k, v)
+	}
+	return h
+}
@@ -0,0 +1,51 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file lets test cases loaded from YAML/JSON select a handler by name instead of only being
+// wireable from Go source.
+
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/cloud-bulldozer/ocm-api-load/pkg/helpers"
+)
+
+var registry = map[string]helpers.HandlerFunc{}
+
+// Register adds h to the registry under name, so that a declarative test-case file can select it
+// with `handler: <name>`. It is meant to be called from an init() function, the same way the
+// built-in handlers are registered below.
+func Register(name string, h helpers.HandlerFunc) {
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("handlers: %q is already registered", name))
+	}
+	registry[name] = h
+}
+
+// Lookup returns the handler registered under name, if any.
+func Lookup(name string) (helpers.HandlerFunc, bool) {
+	h, ok := registry[name]
+	return h, ok
+}
+
+func init() {
+	Register("static-endpoint", TestStaticEndpoint)
+	Register("register-new-cluster", TestRegisterNewCluster)
+	Register("create-cluster", TestCreateCluster)
+	Register("list-clusters", TestListClusters)
+}
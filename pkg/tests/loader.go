@@ -0,0 +1,250 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file lets the test table be extended (or overridden) from a YAML/JSON file instead of
+// requiring a recompile for every new scenario. See LoadTestCases and MergeTestCases.
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/cloud-bulldozer/ocm-api-load/pkg/helpers"
+	"github.com/cloud-bulldozer/ocm-api-load/pkg/tests/handlers"
+)
+
+// TestCase is the on-disk representation of a single test accepted by LoadTestCases. It mirrors
+// helpers.TestOptions but keeps the handler as a registry name and accepts a few alternative body
+// sources so scenarios can be added or tweaked without recompiling.
+type TestCase struct {
+	Name         string            `yaml:"name" json:"name"`
+	Path         string            `yaml:"path" json:"path"`
+	Method       string            `yaml:"method" json:"method"`
+	Handler      string            `yaml:"handler" json:"handler"`
+	Body         string            `yaml:"body" json:"body"`
+	BodyFile     string            `yaml:"body_file" json:"body_file"`
+	BodyTemplate string            `yaml:"body_template" json:"body_template"`
+	Headers      map[string]string `yaml:"headers" json:"headers"`
+}
+
+// TemplateData is exposed to body_template entries so payloads like the access-review request can
+// be parameterized per environment instead of hard-coded in Go.
+type TemplateData struct {
+	Env        map[string]string
+	Account    string
+	ClusterIDs []string
+}
+
+// headersByTest holds the extra headers declared for a loaded test, keyed by test name, so the
+// attack loop can set them without helpers.TestOptions needing a Headers field of its own.
+var headersByTest = map[string]map[string]string{}
+
+// HeadersFor returns the extra headers configured for the named test, or nil if none were
+// declared in the loaded test-case file.
+func HeadersFor(name string) map[string]string {
+	return headersByTest[name]
+}
+
+// LoadTestCases reads a YAML or JSON file of TestCase entries (the format is picked from the file
+// extension) and returns the equivalent []helpers.TestOptions, with handler names resolved
+// against the handlers registry and body_template entries rendered through text/template.
+func LoadTestCases(path string, clusterIDs []string) ([]helpers.TestOptions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading test-case file %q: %w", path, err)
+	}
+
+	var cases []TestCase
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		cases, err = parseYAMLTestCases(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing test-case file %q: %w", path, err)
+		}
+	case ".json":
+		if err := json.Unmarshal(data, &cases); err != nil {
+			return nil, fmt.Errorf("parsing test-case file %q: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported test-case file extension %q, want .yaml, .yml or .json", ext)
+	}
+
+	tmplData := TemplateData{
+		Env:        envMap(),
+		Account:    helpers.AccountUsername,
+		ClusterIDs: clusterIDs,
+	}
+
+	options := make([]helpers.TestOptions, 0, len(cases))
+	for i, c := range cases {
+		opt, err := c.toTestOptions(tmplData)
+		if err != nil {
+			return nil, fmt.Errorf("test case %d (%q): %w", i, c.Name, err)
+		}
+		if len(c.Headers) > 0 {
+			headersByTest[c.Name] = c.Headers
+		}
+		options = append(options, opt)
+	}
+	return options, nil
+}
+
+func (c TestCase) validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if c.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	switch c.Method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return fmt.Errorf("method %q is not a supported HTTP method", c.Method)
+	}
+	if c.Handler == "" {
+		return fmt.Errorf("handler is required")
+	}
+	set := 0
+	for _, has := range []bool{c.Body != "", c.BodyFile != "", c.BodyTemplate != ""} {
+		if has {
+			set++
+		}
+	}
+	if set > 1 {
+		return fmt.Errorf("only one of body, body_file or body_template may be set")
+	}
+	return nil
+}
+
+func (c TestCase) toTestOptions(data TemplateData) (helpers.TestOptions, error) {
+	if err := c.validate(); err != nil {
+		return helpers.TestOptions{}, err
+	}
+
+	handler, ok := handlers.Lookup(c.Handler)
+	if !ok {
+		return helpers.TestOptions{}, fmt.Errorf("no handler registered with name %q", c.Handler)
+	}
+
+	body, err := c.renderBody(data)
+	if err != nil {
+		return helpers.TestOptions{}, err
+	}
+
+	return helpers.TestOptions{
+		TestName: c.Name,
+		Path:     c.Path,
+		Method:   c.Method,
+		Handler:  handler,
+		Body:     body,
+	}, nil
+}
+
+func (c TestCase) renderBody(data TemplateData) ([]byte, error) {
+	switch {
+	case c.BodyFile != "":
+		body, err := os.ReadFile(c.BodyFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading body_file %q: %w", c.BodyFile, err)
+		}
+		return body, nil
+	case c.BodyTemplate != "":
+		tmpl, err := template.New(c.Name).Parse(c.BodyTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("parsing body_template: %w", err)
+		}
+		buf := &bytes.Buffer{}
+		if err := tmpl.Execute(buf, data); err != nil {
+			return nil, fmt.Errorf("rendering body_template: %w", err)
+		}
+		return buf.Bytes(), nil
+	case c.Body != "":
+		return []byte(c.Body), nil
+	default:
+		return nil, nil
+	}
+}
+
+func envMap() map[string]string {
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 2 {
+			env[parts[0]] = parts[1]
+		}
+	}
+	return env
+}
+
+// MergeTestCases returns the built-in test table with extra appended, replacing any built-in
+// entry that shares a name with one of extra so a test-case file can override a single scenario
+// without having to repeat every other one.
+func MergeTestCases(extra []helpers.TestOptions) []helpers.TestOptions {
+	if len(extra) == 0 {
+		return tests
+	}
+	overridden := make(map[string]bool, len(extra))
+	for _, t := range extra {
+		overridden[t.TestName] = true
+	}
+
+	merged := make([]helpers.TestOptions, 0, len(tests)+len(extra))
+	for _, t := range tests {
+		if overridden[t.TestName] {
+			continue
+		}
+		merged = append(merged, t)
+	}
+	merged = append(merged, extra...)
+	return merged
+}
+
+// printableTest is what --print-tests actually dumps: helpers.TestOptions carries a Handler func,
+// which can't be JSON-encoded, so this mirrors the parts of the effective plan a user needs to
+// confirm before a run.
+type printableTest struct {
+	Name    string            `json:"name"`
+	Path    string            `json:"path"`
+	Method  string            `json:"method"`
+	BodyLen int               `json:"body_bytes"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// PrintTests writes the effective, merged test plan to w as indented JSON, for the --print-tests
+// CLI flag.
+func PrintTests(w io.Writer, plan []helpers.TestOptions) error {
+	printable := make([]printableTest, 0, len(plan))
+	for _, t := range plan {
+		printable = append(printable, printableTest{
+			Name:    t.TestName,
+			Path:    t.Path,
+			Method:  t.Method,
+			BodyLen: len(t.Body),
+			Headers: headersByTest[t.TestName],
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(printable)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file parses the flat, list-of-objects YAML shape LoadTestCases accepts, by hand rather
+// than pulling in a full YAML library: a top-level sequence of mappings with scalar values and at
+// most one nested "headers" mapping. It intentionally does not attempt to support the rest of the
+// YAML spec (multi-line scalars, anchors, arbitrary nesting, ...) - a TestCase file that needs
+// more than that should be written as JSON instead.
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// parseYAMLTestCases parses data as a sequence of test-case mappings, e.g.:
+//
+//	# one test case with a nested headers mapping
+//	- name: access-review
+//	  path: /api/authorizations/v1/access_review
+//	  method: POST
+//	  headers:
+//	    X-Impersonate-User: test-user
+//	  handler: static-endpoint
+//
+// A nested mapping like headers above is only read while its lines are indented further than the
+// key that introduced it; a line back at that key's own indent - handler here, even though it
+// comes after headers - ends the nested mapping and is parsed as a field of the test case.
+func parseYAMLTestCases(data []byte) ([]TestCase, error) {
+	var items []map[string]interface{}
+	var current map[string]interface{}
+	var headers map[string]string
+	inHeaders := false
+	headersIndent := 0
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		if headers != nil {
+			current["headers"] = headers
+		}
+		items = append(items, current)
+		current = nil
+		headers = nil
+		inHeaders = false
+	}
+
+	for n, raw := range strings.Split(string(data), "\n") {
+		line := strings.TrimRight(raw, " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		if strings.HasPrefix(trimmed, "-") {
+			flush()
+			current = map[string]interface{}{}
+			rest := strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))
+			if rest == "" {
+				continue
+			}
+			if err := setField(current, rest); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			continue
+		}
+
+		if current == nil {
+			return nil, fmt.Errorf("line %d: expected a list item starting with \"-\", got %q", n+1, trimmed)
+		}
+
+		if trimmed == "headers:" {
+			inHeaders = true
+			headersIndent = indent
+			headers = map[string]string{}
+			continue
+		}
+		if inHeaders && indent > headersIndent {
+			key, value, err := splitKV(trimmed)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			headers[key] = value
+			continue
+		}
+		inHeaders = false
+		if err := setField(current, trimmed); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+	}
+	flush()
+
+	// Reuse TestCase's existing json tags instead of hand-mapping every field twice.
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("internal error building test cases: %w", err)
+	}
+	var cases []TestCase
+	if err := json.Unmarshal(raw, &cases); err != nil {
+		return nil, fmt.Errorf("internal error building test cases: %w", err)
+	}
+	return cases, nil
+}
+
+func setField(item map[string]interface{}, kv string) error {
+	key, value, err := splitKV(kv)
+	if err != nil {
+		return err
+	}
+	item[key] = value
+	return nil
+}
+
+func splitKV(s string) (key string, value string, err error) {
+	idx := strings.Index(s, ":")
+	if idx < 0 {
+		return "", "", fmt.Errorf("expected \"key: value\", got %q", s)
+	}
+	key = strings.TrimSpace(s[:idx])
+	value = unquote(strings.TrimSpace(s[idx+1:]))
+	return key, value, nil
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 {
+		if (s[0] == '"' && s[len(s)-1] == '"') || (s[0] == '\'' && s[len(s)-1] == '\'') {
+			return s[1 : len(s)-1]
+		}
+	}
+	return s
+}
@@ -0,0 +1,97 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import "testing"
+
+func TestParseYAMLTestCases(t *testing.T) {
+	data := []byte(`
+- name: access-review
+  path: /api/authorizations/v1/access_review
+  method: POST
+  headers:
+    X-Impersonate-User: test-user
+  handler: static-endpoint
+- name: list-clusters
+  path: /api/clusters_mgmt/v1/clusters
+  method: GET
+  handler: list-clusters
+`)
+
+	cases, err := parseYAMLTestCases(data)
+	if err != nil {
+		t.Fatalf("parseYAMLTestCases returned an error: %s", err)
+	}
+	if len(cases) != 2 {
+		t.Fatalf("expected 2 test cases, got %d", len(cases))
+	}
+
+	first := cases[0]
+	if first.Handler != "static-endpoint" {
+		t.Errorf("expected first case's handler to be %q, got %q", "static-endpoint", first.Handler)
+	}
+	if got := first.Headers["X-Impersonate-User"]; got != "test-user" {
+		t.Errorf("expected header X-Impersonate-User to be %q, got %q", "test-user", got)
+	}
+	if _, ok := first.Headers["handler"]; ok {
+		t.Errorf("handler leaked into headers: %#v", first.Headers)
+	}
+
+	second := cases[1]
+	if second.Name != "list-clusters" {
+		t.Errorf("expected second case's name to be %q, got %q", "list-clusters", second.Name)
+	}
+	if len(second.Headers) != 0 {
+		t.Errorf("expected second case to have no headers, got %#v", second.Headers)
+	}
+}
+
+func TestParseYAMLTestCasesHeadersLast(t *testing.T) {
+	data := []byte(`
+- name: access-review
+  path: /api/authorizations/v1/access_review
+  method: POST
+  handler: static-endpoint
+  headers:
+    X-Impersonate-User: test-user
+`)
+
+	cases, err := parseYAMLTestCases(data)
+	if err != nil {
+		t.Fatalf("parseYAMLTestCases returned an error: %s", err)
+	}
+	if len(cases) != 1 {
+		t.Fatalf("expected 1 test case, got %d", len(cases))
+	}
+	if got := cases[0].Headers["X-Impersonate-User"]; got != "test-user" {
+		t.Errorf("expected header X-Impersonate-User to be %q, got %q", "test-user", got)
+	}
+}
+
+func TestParseYAMLTestCasesRejectsMissingDash(t *testing.T) {
+	data := []byte(`name: access-review`)
+	if _, err := parseYAMLTestCases(data); err == nil {
+		t.Fatal("expected an error for a line outside any list item, got nil")
+	}
+}
+
+func TestParseYAMLTestCasesRejectsBadField(t *testing.T) {
+	data := []byte(`- name access-review`)
+	if _, err := parseYAMLTestCases(data); err == nil {
+		t.Fatal("expected an error for a field without a colon, got nil")
+	}
+}